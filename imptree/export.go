@@ -0,0 +1,197 @@
+// Copyright 2022 tobbstr. All rights reserved.
+// Use of this source code is governed by a MIT-
+// license that can be found in the LICENSE file.
+package imptree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ExportOptions configures how WriteDOT and WriteMermaid render an import tree.
+type ExportOptions struct {
+	// HighlightRoot, if true, styles root distinctly so it stands out in the rendered graph.
+	HighlightRoot bool
+	// ModulePrefixes groups nodes into clusters by the first prefix in this list that their PkgPath
+	// starts with, preserving the given order. Nodes matching no prefix are rendered outside any cluster.
+	ModulePrefixes []string
+	// LabelSCCEdges, if true, labels every edge touching a collapsed SCC node (see Node.SCCMembers) to
+	// call out that the edge crosses an import cycle.
+	LabelSCCEdges bool
+}
+
+// WriteDOT writes a GraphViz DOT representation of the import tree rooted at root to w. The output can be
+// piped to `dot -Tsvg` (or similar) to visualize the import graph.
+func WriteDOT(w io.Writer, root *Node, opts ExportOptions) error {
+	nodes, edges := collectGraph(root)
+	clusters, ungrouped := groupByPrefix(nodes, opts.ModulePrefixes)
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph imptree {\n")
+	buf.WriteString("\trankdir=LR;\n")
+
+	for i, prefix := range opts.ModulePrefixes {
+		members := clusters[prefix]
+		if len(members) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\tsubgraph cluster_%d {\n", i)
+		fmt.Fprintf(&buf, "\t\tlabel=%q;\n", prefix)
+		for _, n := range members {
+			fmt.Fprintf(&buf, "\t\t%s;\n", dotNode(n, root, opts))
+		}
+		buf.WriteString("\t}\n")
+	}
+	for _, n := range ungrouped {
+		fmt.Fprintf(&buf, "\t%s;\n", dotNode(n, root, opts))
+	}
+
+	for _, e := range edges {
+		fmt.Fprintf(&buf, "\t%q -> %q%s;\n", e.from.PkgPath, e.to.PkgPath, dotEdgeLabel(e, opts))
+	}
+
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ToDOT renders root as a GraphViz DOT graph using default ExportOptions and returns it as a string.
+func ToDOT(root *Node) string {
+	var buf bytes.Buffer
+	_ = WriteDOT(&buf, root, ExportOptions{})
+	return buf.String()
+}
+
+// WriteMermaid writes a Mermaid flowchart representation of the import tree rooted at root to w, suitable
+// for embedding in Markdown via a ```mermaid code block.
+func WriteMermaid(w io.Writer, root *Node, opts ExportOptions) error {
+	nodes, edges := collectGraph(root)
+	ids := assignMermaidIDs(nodes)
+	clusters, ungrouped := groupByPrefix(nodes, opts.ModulePrefixes)
+
+	var buf bytes.Buffer
+	buf.WriteString("graph LR\n")
+
+	for i, prefix := range opts.ModulePrefixes {
+		members := clusters[prefix]
+		if len(members) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\tsubgraph cluster%d[%q]\n", i, prefix)
+		for _, n := range members {
+			fmt.Fprintf(&buf, "\t\t%s[%q]\n", ids[n], nodeLabel(n))
+		}
+		buf.WriteString("\tend\n")
+	}
+	for _, n := range ungrouped {
+		fmt.Fprintf(&buf, "\t%s[%q]\n", ids[n], nodeLabel(n))
+	}
+
+	if opts.HighlightRoot {
+		fmt.Fprintf(&buf, "\tstyle %s fill:#8ecae6\n", ids[root])
+	}
+
+	for _, e := range edges {
+		fmt.Fprintf(&buf, "\t%s -->%s %s\n", ids[e.from], mermaidEdgeLabel(e, opts), ids[e.to])
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// edge is a directed Children link from one Node to another, collected once per pair regardless of how
+// many times it's reachable from root.
+type edge struct {
+	from, to *Node
+}
+
+// collectGraph walks root's Children links and returns every reachable Node (sorted by PkgPath for
+// deterministic output) along with every edge between them.
+func collectGraph(root *Node) (nodes []*Node, edges []edge) {
+	visited := make(map[*Node]bool)
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		nodes = append(nodes, n)
+		for _, child := range n.Children {
+			edges = append(edges, edge{from: n, to: child})
+			walk(child)
+		}
+	}
+	walk(root)
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].PkgPath < nodes[j].PkgPath })
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].from.PkgPath != edges[j].from.PkgPath {
+			return edges[i].from.PkgPath < edges[j].from.PkgPath
+		}
+		return edges[i].to.PkgPath < edges[j].to.PkgPath
+	})
+
+	return nodes, edges
+}
+
+// groupByPrefix buckets nodes by the first prefix in prefixes that their PkgPath starts with. A node
+// matching no prefix is returned in ungrouped instead.
+func groupByPrefix(nodes []*Node, prefixes []string) (clusters map[string][]*Node, ungrouped []*Node) {
+	clusters = make(map[string][]*Node)
+nodeLoop:
+	for _, n := range nodes {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(n.PkgPath, prefix) {
+				clusters[prefix] = append(clusters[prefix], n)
+				continue nodeLoop
+			}
+		}
+		ungrouped = append(ungrouped, n)
+	}
+	return clusters, ungrouped
+}
+
+// nodeLabel returns the text used to label n: its PkgPath, or, for a collapsed SCC, its members listed one
+// per line.
+func nodeLabel(n *Node) string {
+	if len(n.SCCMembers) == 0 {
+		return n.PkgPath
+	}
+	return strings.Join(n.SCCMembers, "\n")
+}
+
+func dotNode(n, root *Node, opts ExportOptions) string {
+	attrs := fmt.Sprintf("label=%q", nodeLabel(n))
+	if opts.HighlightRoot && n == root {
+		attrs += `,style=filled,fillcolor=lightblue`
+	}
+	return fmt.Sprintf("%q [%s]", n.PkgPath, attrs)
+}
+
+func dotEdgeLabel(e edge, opts ExportOptions) string {
+	if !opts.LabelSCCEdges || (len(e.from.SCCMembers) == 0 && len(e.to.SCCMembers) == 0) {
+		return ""
+	}
+	return ` [label="cycle"]`
+}
+
+func mermaidEdgeLabel(e edge, opts ExportOptions) string {
+	if !opts.LabelSCCEdges || (len(e.from.SCCMembers) == 0 && len(e.to.SCCMembers) == 0) {
+		return ""
+	}
+	return `|cycle|`
+}
+
+// assignMermaidIDs gives every node a short, Mermaid-safe identifier (n0, n1, ...) in PkgPath order, since
+// import paths themselves may contain characters Mermaid doesn't accept in a bare node ID.
+func assignMermaidIDs(nodes []*Node) map[*Node]string {
+	ids := make(map[*Node]string, len(nodes))
+	for i, n := range nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+	}
+	return ids
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 tobbstr. All rights reserved.
+// Use of this source code is governed by a MIT-
+// license that can be found in the LICENSE file.
+package imptree
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// exportTestTree builds the same "a -> a/a, a -> c -> {d, a/a}" tree used elsewhere in this package's
+// tests, so the golden files stay easy to eyeball against TestBuilder_Build's happy path.
+func exportTestTree() *Node {
+	nodeAA := &Node{PkgPath: "a/a"}
+	nodeD := &Node{PkgPath: "d"}
+	nodeC := &Node{PkgPath: "c", Children: []*Node{nodeD, nodeAA}}
+	nodeD.Parents = []*Node{nodeC}
+	nodeA := &Node{PkgPath: "a", Children: []*Node{nodeAA, nodeC}}
+	nodeAA.Parents = []*Node{nodeA, nodeC}
+	nodeC.Parents = []*Node{nodeA}
+
+	return nodeA
+}
+
+func TestWriteDOT(t *testing.T) {
+	// Given
+	require := require.New(t)
+	root := exportTestTree()
+	want, err := os.ReadFile("testdata/tree.dot")
+	require.NoError(err)
+
+	// When
+	var buf bytes.Buffer
+	err = WriteDOT(&buf, root, ExportOptions{HighlightRoot: true, ModulePrefixes: []string{"a"}})
+
+	// Then
+	require.NoError(err)
+	require.Equal(string(want), buf.String())
+}
+
+func TestToDOT(t *testing.T) {
+	// Given
+	require := require.New(t)
+	root := exportTestTree()
+
+	var buf bytes.Buffer
+	err := WriteDOT(&buf, root, ExportOptions{})
+	require.NoError(err)
+
+	// When
+	got := ToDOT(root)
+
+	// Then
+	require.Equal(buf.String(), got)
+}
+
+func TestWriteMermaid(t *testing.T) {
+	// Given
+	require := require.New(t)
+	root := exportTestTree()
+	want, err := os.ReadFile("testdata/tree.mmd")
+	require.NoError(err)
+
+	// When
+	var buf bytes.Buffer
+	err = WriteMermaid(&buf, root, ExportOptions{HighlightRoot: true, ModulePrefixes: []string{"a"}})
+
+	// Then
+	require.NoError(err)
+	require.Equal(string(want), buf.String())
+}
+
+func TestWriteDOT_labelsSCCEdges(t *testing.T) {
+	// Given
+	require := require.New(t)
+	sccNode := &Node{PkgPath: "a", SCCMembers: []string{"a", "b"}}
+	leaf := &Node{PkgPath: "d"}
+	sccNode.Children = []*Node{leaf}
+	leaf.Parents = []*Node{sccNode}
+
+	// When
+	got := ToDOT(sccNode)
+
+	// Then: SCC members are listed in the node label even without LabelSCCEdges.
+	require.Contains(got, `label="a\nb"`)
+
+	var buf bytes.Buffer
+	err := WriteDOT(&buf, sccNode, ExportOptions{LabelSCCEdges: true})
+	require.NoError(err)
+	require.Contains(buf.String(), `label="cycle"`)
+}
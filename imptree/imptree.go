@@ -6,6 +6,7 @@ package imptree
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -21,13 +22,25 @@ type Node struct {
 	Parents []*Node
 	// PkgPath is the import path to this package
 	PkgPath string
+	// SCCMembers lists the import paths of every package collapsed into this node because they form a
+	// strongly-connected component (an import cycle). It is nil for nodes that aren't part of a cycle.
+	SCCMembers []string
 }
 
 // Builder is a tree builder. A Builder should not be reused for different trees, instead a new Builder should
 // be instantiated.
 type Builder struct {
-	// nodes maps import paths to Nodes
+	// nodes maps import paths to Nodes. When collapseCycles is enabled, the key for a node inside a cycle
+	// is the lexicographically smallest PkgPath among its SCCMembers.
 	nodes map[string]*Node
+	// collapseCycles controls how import cycles are represented in the resulting tree. See CollapseCycles.
+	collapseCycles bool
+	// buildFlags are extra flags passed to the underlying go command when loading packages. See WithBuildFlags.
+	buildFlags []string
+	// env holds extra environment variables used when loading packages. See WithEnv.
+	env []string
+	// mode overrides the default packages.LoadMode used when loading packages. See WithMode.
+	mode packages.LoadMode
 	// loadPkgs is a hook that allows for testing.
 	// See https://pkg.go.dev/golang.org/x/tools/go/packages#Load for details regarding its actual
 	// implementation.
@@ -47,6 +60,58 @@ func NewBuilder() *Builder {
 	}
 }
 
+// CollapseCycles controls how import cycles are represented in the tree returned by Build. When collapse is
+// true, every strongly-connected component of the import graph is collapsed into a single Node, whose
+// PkgPath is the lexicographically smallest member and whose SCCMembers lists every package in the cycle.
+// When collapse is false (the default), each package keeps its own Node and cycles simply result in Nodes
+// that are both ancestors and descendants of each other. CollapseCycles returns b so calls can be chained
+// onto NewBuilder.
+func (b *Builder) CollapseCycles(collapse bool) *Builder {
+	b.collapseCycles = collapse
+	return b
+}
+
+// WithBuildFlags sets extra flags (e.g. "-tags=integration") passed to the underlying go command when
+// loading packages. It's threaded into packages.Config.BuildFlags. WithBuildFlags returns b so calls can be
+// chained onto NewBuilder.
+func (b *Builder) WithBuildFlags(flags []string) *Builder {
+	b.buildFlags = flags
+	return b
+}
+
+// WithEnv appends env on top of the current process's environment when loading packages, e.g.
+// []string{"GOOS=windows", "GOARCH=arm64"} to analyze the import graph as it would appear on that platform.
+// As with os/exec, later entries win over earlier ones that share a key. WithEnv returns b so calls can be
+// chained onto NewBuilder.
+func (b *Builder) WithEnv(env []string) *Builder {
+	b.env = env
+	return b
+}
+
+// WithMode overrides the packages.LoadMode used when loading packages. By default, Build and BuildAll load
+// with packages.NeedImports|packages.NeedName; use WithMode to request additional data packages.Load can
+// provide. WithMode returns b so calls can be chained onto NewBuilder.
+func (b *Builder) WithMode(mode packages.LoadMode) *Builder {
+	b.mode = mode
+	return b
+}
+
+// config builds the packages.Config used to load packages, applying WithBuildFlags, WithEnv and WithMode.
+func (b *Builder) config() *packages.Config {
+	cfg := &packages.Config{}
+	// Bypass default vendor mode, as we need a package not available in the
+	// std module vendor folder.
+	cfg.Env = append(append(os.Environ(), "GOFLAGS=-mod=mod"), b.env...)
+	cfg.BuildFlags = b.buildFlags
+
+	cfg.Mode = b.mode
+	if cfg.Mode == 0 {
+		cfg.Mode = packages.NeedImports | packages.NeedName
+	}
+
+	return cfg
+}
+
 // Build builds and returns a doubly-linked tree of import paths, so it's possible to see which packages are
 // imported by a package (its children) and also which packages import a package (its parents). The tree's
 // root package is given by importPath. Only packages matched by matchPkg are included in the tree.
@@ -60,11 +125,7 @@ func NewBuilder() *Builder {
 //		return false
 //	})
 func (b *Builder) Build(importPath string, matchPkg MatchPkg) (*Node, error) {
-	cfg := &packages.Config{}
-	// Bypass default vendor mode, as we need a package not available in the
-	// std module vendor folder.
-	cfg.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
-	cfg.Mode = packages.NeedImports | packages.NeedName
+	cfg := b.config()
 
 	// pkgs, err := packages.Load(cfg, importPath)
 	pkgs, err := b.loadPkgs(cfg, importPath)
@@ -77,57 +138,205 @@ func (b *Builder) Build(importPath string, matchPkg MatchPkg) (*Node, error) {
 	}
 	pkg := pkgs[0]
 
-	// build tree
-	b.buildTree(pkg, matchPkg)
+	// find strongly-connected components first, so buildTree can treat import cycles correctly
+	// regardless of whether they're collapsed into a single Node.
+	st := newTarjanState()
+	st.run(pkg, matchPkg)
+
+	// build tree and return its root node directly. We can't find the root by walking Node.Parents up to
+	// nil the way earlier versions did, because an uncollapsed cycle reachable from the root leaves no
+	// Node with nil Parents to walk up to.
+	root := b.buildTree(pkg, matchPkg, st, make(map[string]bool))
+	if root == nil {
+		return nil, fmt.Errorf("could not find tree root node")
+	}
+
+	return root, nil
+}
+
+// BuildAll builds and returns one tree per package matched by the given `./...`-style patterns, so a whole
+// module (or a subset of it) can be analyzed in one call instead of one entry package at a time. Every
+// loaded package is included in its tree; unlike Build, there's no matchPkg predicate to narrow the set,
+// since the patterns already scope it. Nodes shared between the resulting trees (because one loaded package
+// imports another) are the same *Node instance, so the returned roots together form an import forest over
+// the matched packages.
+func (b *Builder) BuildAll(patterns ...string) ([]*Node, error) {
+	cfg := b.config()
+
+	pkgs, err := b.loadPkgs(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if b.printLoadPkgsErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("failed to load source packages")
+	}
+
+	matchAll := func(*packages.Package) bool { return true }
+	visited := make(map[string]bool)
+
+	roots := make([]*Node, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		st := newTarjanState()
+		st.run(pkg, matchAll)
+
+		if root := b.buildTree(pkg, matchAll, st, visited); root != nil {
+			roots = append(roots, root)
+		}
+	}
+
+	return roots, nil
+}
+
+// buildTree links Nodes for pkg and its matched imports, recursing into each import exactly once, and
+// returns the Node created for pkg (or nil if pkg is excluded by matchPkg). visited is keyed by the real
+// PkgPath of the underlying *packages.Package (not the, possibly collapsed, Node key) so that cycles in the
+// import graph always terminate the recursion, whether or not b.collapseCycles is set.
+func (b *Builder) buildTree(pkg *packages.Package, matchPkg MatchPkg, st *tarjanState, visited map[string]bool) *Node {
+	if !matchPkg(pkg) {
+		return nil
+	}
+	if visited[pkg.PkgPath] {
+		return b.nodeFor(pkg, st)
+	}
+	visited[pkg.PkgPath] = true
+
+	node := b.nodeFor(pkg, st)
+
+	for _, childPkg := range pkg.Imports {
+		if !matchPkg(childPkg) {
+			continue
+		}
+
+		childNode := b.nodeFor(childPkg, st)
+
+		// Skip self-edges created when two packages in the same (collapsed) SCC import one another.
+		if childNode != node {
+			if !containsNode(childNode.Parents, node) {
+				childNode.Parents = append(childNode.Parents, node)
+			}
 
-	// find tree root node and return it
-	for _, node := range b.nodes {
-		for node.Parents != nil {
-			node = node.Parents[0]
+			if !containsNode(node.Children, childNode) {
+				node.Children = append(node.Children, childNode)
+			}
 		}
 
-		return node, nil
+		b.buildTree(childPkg, matchPkg, st, visited)
 	}
 
-	return nil, fmt.Errorf("could not find tree root node")
+	return node
 }
 
-func (b *Builder) buildTree(pkg *packages.Package, matchPkg MatchPkg) {
+// nodeFor returns the Node for pkg, creating it if necessary. When b.collapseCycles is set and pkg belongs
+// to a multi-package SCC, the returned Node is shared by every member of that SCC.
+func (b *Builder) nodeFor(pkg *packages.Package, st *tarjanState) *Node {
+	key := pkg.PkgPath
+	var members []string
+	if b.collapseCycles {
+		if rep, ok := st.sccOf[pkg.PkgPath]; ok {
+			key = rep
+			members = st.members[rep]
+		}
+	}
+
+	if n, ok := b.nodes[key]; ok {
+		return n
+	}
+
+	node := &Node{PkgPath: key, SCCMembers: members}
+	b.nodes[key] = node
+	return node
+}
+
+// tarjanState holds the bookkeeping for a single run of Tarjan's strongly-connected-components algorithm
+// over the import graph induced by a MatchPkg predicate.
+type tarjanState struct {
+	index   map[string]int
+	lowlink map[string]int
+	onStack map[string]bool
+	stack   []string
+	next    int
+	// sccOf maps every package path that participates in a cycle to its SCC's representative path (the
+	// lexicographically smallest member). Packages not part of any cycle are absent from the map.
+	sccOf map[string]string
+	// members maps an SCC's representative path to the sorted list of every package path in that SCC.
+	members map[string][]string
+}
+
+func newTarjanState() *tarjanState {
+	return &tarjanState{
+		index:   make(map[string]int),
+		lowlink: make(map[string]int),
+		onStack: make(map[string]bool),
+		sccOf:   make(map[string]string),
+		members: make(map[string][]string),
+	}
+}
+
+// run computes SCCs for the subgraph of pkg's transitive imports matched by matchPkg.
+func (st *tarjanState) run(pkg *packages.Package, matchPkg MatchPkg) {
 	if !matchPkg(pkg) {
 		return
 	}
+	st.strongconnect(pkg, matchPkg)
+}
 
-	var node *Node
-	if n, ok := b.nodes[pkg.PkgPath]; ok {
-		node = n
-	} else {
-		node = &Node{PkgPath: pkg.PkgPath}
-		b.nodes[node.PkgPath] = node
+func (st *tarjanState) strongconnect(pkg *packages.Package, matchPkg MatchPkg) {
+	path := pkg.PkgPath
+	if _, ok := st.index[path]; ok {
+		return
 	}
 
+	st.index[path] = st.next
+	st.lowlink[path] = st.next
+	st.next++
+	st.stack = append(st.stack, path)
+	st.onStack[path] = true
+
 	for importPath, childPkg := range pkg.Imports {
 		if !matchPkg(childPkg) {
 			continue
 		}
 
-		var childNode *Node
-		if cn, ok := b.nodes[importPath]; ok {
-			childNode = cn
-		} else {
-			childNode = &Node{PkgPath: importPath}
-			b.nodes[importPath] = childNode
+		if _, ok := st.index[importPath]; !ok {
+			st.strongconnect(childPkg, matchPkg)
+			if st.lowlink[childPkg.PkgPath] < st.lowlink[path] {
+				st.lowlink[path] = st.lowlink[childPkg.PkgPath]
+			}
+		} else if st.onStack[importPath] {
+			if st.index[importPath] < st.lowlink[path] {
+				st.lowlink[path] = st.index[importPath]
+			}
 		}
+	}
 
-		if !containsNode(childNode.Parents, node) {
-			childNode.Parents = append(childNode.Parents, node)
-		}
+	if st.lowlink[path] != st.index[path] {
+		return
+	}
 
-		if !containsNode(node.Children, childNode) {
-			node.Children = append(node.Children, childNode)
+	// path is the root of an SCC; pop its members off the stack.
+	var members []string
+	for {
+		n := st.stack[len(st.stack)-1]
+		st.stack = st.stack[:len(st.stack)-1]
+		st.onStack[n] = false
+		members = append(members, n)
+		if n == path {
+			break
 		}
+	}
+
+	// Only multi-member SCCs are cycles; a lone package is never recorded in sccOf/members so nodeFor
+	// leaves non-cyclic Nodes untouched.
+	if len(members) < 2 {
+		return
+	}
 
-		b.buildTree(childPkg, matchPkg)
+	sort.Strings(members)
+	rep := members[0]
+	for _, m := range members {
+		st.sccOf[m] = rep
 	}
+	st.members[rep] = members
 }
 
 func containsNode(slc []*Node, node *Node) bool {
@@ -191,37 +191,142 @@ func TestNewBuilder(t *testing.T) {
 	require.Empty(got.nodes)
 }
 
-func Test_removeNodeRecursively(t *testing.T) {
+func TestBuilder_config(t *testing.T) {
 	// Given
 	require := require.New(t)
+	b := (&Builder{}).
+		WithBuildFlags([]string{"-tags=integration"}).
+		WithEnv([]string{"GOOS=windows", "GOARCH=arm64"}).
+		WithMode(packages.NeedImports | packages.NeedName | packages.NeedFiles)
 
-	treeC := &Node{PkgPath: "c"}
-	treeB1 := &Node{PkgPath: "b1"}
-	treeB2 := &Node{PkgPath: "b2"}
-	tree := &Node{PkgPath: "root"}
-
-	treeC.Parents = []*Node{treeB1, treeB2}
-	treeB1.Parents = []*Node{tree}
-	treeB2.Children = []*Node{treeC}
-	treeB2.Parents = []*Node{tree}
-	tree.Children = []*Node{treeB1, treeB2}
+	// When
+	cfg := b.config()
 
-	cRemovedTreeB1 := &Node{PkgPath: "b1"}
-	cRemovedTreeB2 := &Node{PkgPath: "b2"}
-	cRemovedTree := &Node{PkgPath: "root"}
+	// Then
+	require.Equal([]string{"-tags=integration"}, cfg.BuildFlags)
+	require.Contains(cfg.Env, "GOOS=windows")
+	require.Contains(cfg.Env, "GOARCH=arm64")
+	require.Contains(cfg.Env, "GOFLAGS=-mod=mod")
+	require.Equal(packages.NeedImports|packages.NeedName|packages.NeedFiles, cfg.Mode)
+}
 
-	cRemovedTreeB1.Parents = []*Node{cRemovedTree}
-	cRemovedTreeB2.Parents = []*Node{cRemovedTree}
-	cRemovedTree.Children = []*Node{cRemovedTreeB1, cRemovedTreeB2}
+func TestBuilder_config_defaultMode(t *testing.T) {
+	// Given
+	require := require.New(t)
+	b := &Builder{}
 
 	// When
-	removeNodeRecursively(tree, tree.Children[1].Children[0]) // node c
+	cfg := b.config()
 
 	// Then
-	require.Len(cRemovedTree.Children, 2)
-	require.Len(cRemovedTree.Children[0].Children, 0)
-	require.Len(cRemovedTree.Children[1].Children, 0)
+	require.Equal(packages.NeedImports|packages.NeedName, cfg.Mode)
+}
+
+func TestBuilder_BuildAll(t *testing.T) {
+	// Given
+	require := require.New(t)
+	pkgA := &packages.Package{
+		PkgPath: "a",
+		Imports: map[string]*packages.Package{
+			"c": {PkgPath: "c"},
+		},
+	}
+	pkgB := &packages.Package{
+		PkgPath: "b",
+		Imports: map[string]*packages.Package{
+			"c": pkgA.Imports["c"],
+		},
+	}
+	b := &Builder{
+		nodes: make(map[string]*Node),
+		loadPkgs: func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+			return []*packages.Package{pkgA, pkgB}, nil
+		},
+		printLoadPkgsErrors: func(pkgs []*packages.Package) int { return 0 },
+	}
+
+	// When
+	roots, err := b.BuildAll("./...")
+
+	// Then: both entry packages get their own root, sharing the single Node for their common import.
+	require.NoError(err)
+	require.Len(roots, 2)
+	require.Equal("a", roots[0].PkgPath)
+	require.Equal("b", roots[1].PkgPath)
+	require.Same(b.nodes["c"], roots[0].Children[0])
+	require.Same(b.nodes["c"], roots[1].Children[0])
+}
+
+// cyclicPkgs builds a fabricated *packages.Package graph with a cycle: a -> b -> c -> a, plus a
+// non-cyclic leaf d imported by c, so tests can tell cyclic and non-cyclic packages apart.
+func cyclicPkgs() *packages.Package {
+	pkgA := &packages.Package{PkgPath: "a"}
+	pkgB := &packages.Package{PkgPath: "b"}
+	pkgC := &packages.Package{PkgPath: "c"}
+	pkgD := &packages.Package{PkgPath: "d"}
+
+	pkgA.Imports = map[string]*packages.Package{"b": pkgB}
+	pkgB.Imports = map[string]*packages.Package{"c": pkgC}
+	pkgC.Imports = map[string]*packages.Package{"a": pkgA, "d": pkgD}
+
+	return pkgA
+}
+
+func matchAll(*packages.Package) bool { return true }
+
+func TestBuilder_Build_cycleWithoutCollapsing(t *testing.T) {
+	// Given
+	require := require.New(t)
+	root := cyclicPkgs()
+	b := &Builder{
+		nodes: make(map[string]*Node),
+		loadPkgs: func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+			return []*packages.Package{root}, nil
+		},
+		printLoadPkgsErrors: func(pkgs []*packages.Package) int { return 0 },
+	}
+
+	// When
+	got, err := b.Build("a", matchAll)
+
+	// Then: recursion terminates and every package in the cycle keeps its own Node.
+	require.NoError(err)
+	require.NotNil(got)
+	require.Len(b.nodes, 4)
+	for _, path := range []string{"a", "b", "c", "d"} {
+		require.Contains(b.nodes, path)
+		require.Empty(b.nodes[path].SCCMembers)
+	}
+
+	nodeA, nodeB, nodeC := b.nodes["a"], b.nodes["b"], b.nodes["c"]
+	require.True(containsNode(nodeA.Children, nodeB))
+	require.True(containsNode(nodeB.Children, nodeC))
+	require.True(containsNode(nodeC.Children, nodeA))
+}
+
+func TestBuilder_Build_cycleWithCollapsing(t *testing.T) {
+	// Given
+	require := require.New(t)
+	root := cyclicPkgs()
+	b := (&Builder{
+		nodes: make(map[string]*Node),
+		loadPkgs: func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+			return []*packages.Package{root}, nil
+		},
+		printLoadPkgsErrors: func(pkgs []*packages.Package) int { return 0 },
+	}).CollapseCycles(true)
+
+	// When
+	got, err := b.Build("a", matchAll)
+
+	// Then: a, b and c collapse into a single Node keyed by the lexicographically smallest member.
+	require.NoError(err)
+	require.NotNil(got)
+	require.Len(b.nodes, 2)
+	require.Contains(b.nodes, "a")
+	require.Contains(b.nodes, "d")
 
-	require.Equal(cRemovedTree.Children[0].PkgPath, "b1")
-	require.Equal(cRemovedTree.Children[1].PkgPath, "b2")
+	sccNode := b.nodes["a"]
+	require.Equal([]string{"a", "b", "c"}, sccNode.SCCMembers)
+	require.True(containsNode(sccNode.Children, b.nodes["d"]))
 }
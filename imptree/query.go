@@ -0,0 +1,131 @@
+// Copyright 2022 tobbstr. All rights reserved.
+// Use of this source code is governed by a MIT-
+// license that can be found in the LICENSE file.
+package imptree
+
+import "sort"
+
+// LookupByPath returns the Node for pkgPath if it was created by a previous Build or BuildAll call on b,
+// otherwise it returns nil. When CollapseCycles is enabled, pkgPath must be the SCC's representative path
+// (see Node.SCCMembers), not just any member of the cycle.
+func (b *Builder) LookupByPath(pkgPath string) *Node {
+	return b.nodes[pkgPath]
+}
+
+// Ancestors returns every Node that transitively imports n (its parents, their parents, and so on), sorted
+// by PkgPath. n itself is not included.
+func (n *Node) Ancestors() []*Node {
+	return reachable(n, func(m *Node) []*Node { return m.Parents })
+}
+
+// Descendants returns every Node that n transitively imports (its children, their children, and so on),
+// sorted by PkgPath. n itself is not included.
+func (n *Node) Descendants() []*Node {
+	return reachable(n, func(m *Node) []*Node { return m.Children })
+}
+
+// reachable does a breadth-first walk from start following next, returning every distinct Node reached
+// (not including start itself), sorted by PkgPath for deterministic output.
+func reachable(start *Node, next func(*Node) []*Node) []*Node {
+	visited := map[*Node]bool{start: true}
+	queue := append([]*Node{}, next(start)...)
+
+	var result []*Node
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if visited[n] {
+			continue
+		}
+		visited[n] = true
+		result = append(result, n)
+		queue = append(queue, next(n)...)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].PkgPath < result[j].PkgPath })
+	return result
+}
+
+// PathTo returns the shortest chain of imports from n to other, starting with n and ending with other, by
+// breadth-first search over Children links. It returns nil if other isn't reachable from n. This is useful
+// for answering "why does package X depend on package Y?".
+func (n *Node) PathTo(other *Node) []*Node {
+	if n == other {
+		return []*Node{n}
+	}
+
+	visited := map[*Node]bool{n: true}
+	prev := map[*Node]*Node{}
+	queue := []*Node{n}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, child := range cur.Children {
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			prev[child] = cur
+
+			if child == other {
+				return pathFrom(prev, n, other)
+			}
+
+			queue = append(queue, child)
+		}
+	}
+
+	return nil
+}
+
+// pathFrom reconstructs the path from `from` to `to` using the BFS predecessor map prev.
+func pathFrom(prev map[*Node]*Node, from, to *Node) []*Node {
+	path := []*Node{to}
+	for cur := to; cur != from; {
+		cur = prev[cur]
+		path = append(path, cur)
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// Diff compares the sets of packages reachable from a and b (each including its own root) by PkgPath, and
+// reports the difference: added lists Nodes present in b's set but not a's, removed lists Nodes present in
+// a's set but not b's. Both are sorted by PkgPath. This is useful for comparing two Builds of the same
+// entry package taken at different points in time.
+func Diff(a, b *Node) (added, removed []*Node) {
+	setA := reachableSet(a)
+	setB := reachableSet(b)
+
+	for path, n := range setB {
+		if _, ok := setA[path]; !ok {
+			added = append(added, n)
+		}
+	}
+	for path, n := range setA {
+		if _, ok := setB[path]; !ok {
+			removed = append(removed, n)
+		}
+	}
+
+	sort.Slice(added, func(i, j int) bool { return added[i].PkgPath < added[j].PkgPath })
+	sort.Slice(removed, func(i, j int) bool { return removed[i].PkgPath < removed[j].PkgPath })
+
+	return added, removed
+}
+
+// reachableSet returns every Node reachable from root via Children (including root itself), keyed by
+// PkgPath.
+func reachableSet(root *Node) map[string]*Node {
+	set := map[string]*Node{root.PkgPath: root}
+	for _, n := range reachable(root, func(m *Node) []*Node { return m.Children }) {
+		set[n.PkgPath] = n
+	}
+	return set
+}
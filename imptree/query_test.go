@@ -0,0 +1,82 @@
+// Copyright 2022 tobbstr. All rights reserved.
+// Use of this source code is governed by a MIT-
+// license that can be found in the LICENSE file.
+package imptree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func pathOf(nodes []*Node) []string {
+	paths := make([]string, len(nodes))
+	for i, n := range nodes {
+		paths[i] = n.PkgPath
+	}
+	return paths
+}
+
+func TestNode_AncestorsAndDescendants(t *testing.T) {
+	// Given
+	require := require.New(t)
+	root := exportTestTree() // a -> {a/a, c}, c -> {d, a/a}
+	nodeAA := root.Children[0]
+	nodeC := root.Children[1]
+	nodeD := nodeC.Children[0]
+
+	// When / Then
+	require.Equal([]string{"a", "c"}, pathOf(nodeAA.Ancestors()))
+	require.Equal([]string{"a"}, pathOf(nodeC.Ancestors()))
+	require.Empty(root.Ancestors())
+
+	require.Equal([]string{"a/a", "c", "d"}, pathOf(root.Descendants()))
+	require.Empty(nodeD.Descendants())
+	require.Empty(nodeAA.Descendants())
+}
+
+func TestNode_PathTo(t *testing.T) {
+	// Given
+	require := require.New(t)
+	root := exportTestTree()
+	nodeC := root.Children[1]
+	nodeD := nodeC.Children[0]
+
+	// When / Then
+	require.Equal([]string{"a"}, pathOf(root.PathTo(root)))
+	require.Equal([]string{"a", "c", "d"}, pathOf(root.PathTo(nodeD)))
+
+	unreachable := &Node{PkgPath: "z"}
+	require.Nil(root.PathTo(unreachable))
+}
+
+func TestDiff(t *testing.T) {
+	// Given
+	require := require.New(t)
+
+	nodeE := &Node{PkgPath: "e"}
+	nodeD := &Node{PkgPath: "d"}
+	nodeC1 := &Node{PkgPath: "c", Children: []*Node{nodeD}}
+	nodeA1 := &Node{PkgPath: "a", Children: []*Node{nodeC1}}
+
+	nodeC2 := &Node{PkgPath: "c", Children: []*Node{nodeE}}
+	nodeA2 := &Node{PkgPath: "a", Children: []*Node{nodeC2}}
+
+	// When
+	added, removed := Diff(nodeA1, nodeA2)
+
+	// Then
+	require.Equal([]string{"e"}, pathOf(added))
+	require.Equal([]string{"d"}, pathOf(removed))
+}
+
+func TestBuilder_LookupByPath(t *testing.T) {
+	// Given
+	require := require.New(t)
+	root := &Node{PkgPath: "a"}
+	b := &Builder{nodes: map[string]*Node{"a": root}}
+
+	// When / Then
+	require.Same(root, b.LookupByPath("a"))
+	require.Nil(b.LookupByPath("missing"))
+}
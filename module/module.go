@@ -4,7 +4,6 @@
 package module
 
 import (
-	"bufio"
 	"fmt"
 	"go/ast"
 	"go/parser"
@@ -13,29 +12,70 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/ast/astutil"
 )
 
-// NameFrom returns the module name, otherwise it returns an error. The argument path has to be a valid
-// module root path i.e. a path that contains a go.mod file.
-func NameFrom(path string) (string, error) {
-	goModFile, err := os.Open(fmt.Sprintf("%s/go.mod", path))
+// ModuleInfo is the parsed content of a go.mod file.
+type ModuleInfo struct {
+	// Path is the module's import path, as declared by the module directive.
+	Path string
+	// GoVersion is the version declared by the go directive, or empty if the file has none.
+	GoVersion string
+	// Require lists the module's require directives, including indirect ones.
+	Require []*modfile.Require
+	// Replace lists the module's replace directives.
+	Replace []*modfile.Replace
+	// Exclude lists the module's exclude directives.
+	Exclude []*modfile.Exclude
+	// Retract lists the module's retract directives.
+	Retract []*modfile.Retract
+}
+
+// Parse reads and parses the go.mod file in path, otherwise it returns an error. The argument path has to
+// be a valid module root path i.e. a path that contains a go.mod file.
+func Parse(path string) (*ModuleInfo, error) {
+	goModPath := filepath.Join(path, "go.mod")
+	data, err := os.ReadFile(goModPath)
 	if err != nil {
-		log.Fatalf("could not open go.mod: %s", err)
+		return nil, fmt.Errorf("could not read go.mod: %w", err)
 	}
 
-	goModScanner := bufio.NewScanner(goModFile)
-	goModScanner.Split(bufio.ScanLines)
+	f, err := modfile.Parse(goModPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse go.mod: %w", err)
+	}
 
-	for goModScanner.Scan() {
-		line := goModScanner.Text()
-		if strings.Contains(line, "module ") {
-			return line[7:], err
-		}
+	info := &ModuleInfo{
+		Require: f.Require,
+		Replace: f.Replace,
+		Exclude: f.Exclude,
+		Retract: f.Retract,
 	}
-	return "", fmt.Errorf("could not find go.mod file in path = %s", path)
+	if f.Module != nil {
+		info.Path = f.Module.Mod.Path
+	}
+	if f.Go != nil {
+		info.GoVersion = f.Go.Version
+	}
+
+	return info, nil
+}
+
+// NameFrom returns the module name, otherwise it returns an error. The argument path has to be a valid
+// module root path i.e. a path that contains a go.mod file. It's a thin wrapper around Parse for callers
+// that only need the module path.
+func NameFrom(path string) (string, error) {
+	info, err := Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Path == "" {
+		return "", fmt.Errorf("could not find module directive in go.mod file in path = %s", path)
+	}
+
+	return info.Path, nil
 }
 
 // RootPathFromWorkingDir walks back up the file system until it finds a module root folder and
@@ -43,11 +83,11 @@ func NameFrom(path string) (string, error) {
 func RootPathFromWorkingDir() (string, error) {
 	wd, err := os.Getwd()
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	for len(wd) > 1 {
-		if !IsRootPath(wd) {
+		if !fileExists(filepath.Join(wd, "go.mod")) {
 			wd = parentDirTo(wd)
 			continue
 		}
@@ -58,9 +98,53 @@ func RootPathFromWorkingDir() (string, error) {
 	return "", fmt.Errorf("could not find Go module root")
 }
 
-// IsRootPath returns true if path is a module root filepath, otherwise false.
-func IsRootPath(path string) bool {
-	f, err := os.Open(path + "/" + "go.mod")
+// WorkspaceRootFromWorkingDir walks back up the file system until it finds a workspace root folder (one
+// containing a go.work file) and returns its filepath, otherwise it returns an error.
+func WorkspaceRootFromWorkingDir() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for len(wd) > 1 {
+		if Kind(wd) != RootWorkspace {
+			wd = parentDirTo(wd)
+			continue
+		}
+
+		return wd, nil
+	}
+
+	return "", fmt.Errorf("could not find Go workspace root")
+}
+
+// RootKind identifies what kind of root a filepath is, as reported by Kind.
+type RootKind int
+
+const (
+	// RootNone means path is neither a module root nor a workspace root.
+	RootNone RootKind = iota
+	// RootModule means path contains a go.mod file.
+	RootModule
+	// RootWorkspace means path contains a go.work file.
+	RootWorkspace
+)
+
+// Kind returns the RootKind of path: RootWorkspace if it contains a go.work file, RootModule if it
+// contains a go.mod file, otherwise RootNone. A path can be a workspace root and a module root at once;
+// Kind reports RootWorkspace in that case since the workspace is the outermost root.
+func Kind(path string) RootKind {
+	if fileExists(filepath.Join(path, "go.work")) {
+		return RootWorkspace
+	}
+	if fileExists(filepath.Join(path, "go.mod")) {
+		return RootModule
+	}
+	return RootNone
+}
+
+func fileExists(path string) bool {
+	f, err := os.Open(path)
 	if err != nil {
 		return false
 	}
@@ -72,6 +156,46 @@ func parentDirTo(path string) string {
 	return filepath.Dir(path)
 }
 
+// Workspace is the parsed content of a go.work file.
+type Workspace struct {
+	// RootPath is the directory containing the go.work file.
+	RootPath string
+	// GoVersion is the version declared by the go directive, or empty if the file has none.
+	GoVersion string
+	// Members are the filesystem paths of every module referenced by a use directive. Relative use paths are
+	// joined onto RootPath, so they are absolute only when RootPath is.
+	Members []string
+}
+
+// ParseWorkspace reads and parses the go.work file in rootPath, otherwise it returns an error. The argument
+// rootPath has to be a valid workspace root path i.e. a path that contains a go.work file.
+func ParseWorkspace(rootPath string) (*Workspace, error) {
+	goWorkPath := filepath.Join(rootPath, "go.work")
+	data, err := os.ReadFile(goWorkPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read go.work: %w", err)
+	}
+
+	f, err := modfile.ParseWork(goWorkPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse go.work: %w", err)
+	}
+
+	ws := &Workspace{RootPath: rootPath}
+	if f.Go != nil {
+		ws.GoVersion = f.Go.Version
+	}
+	for _, use := range f.Use {
+		member := use.Path
+		if !filepath.IsAbs(member) {
+			member = filepath.Join(rootPath, member)
+		}
+		ws.Members = append(ws.Members, filepath.Clean(member))
+	}
+
+	return ws, nil
+}
+
 // IsMainPkg returns true if the filepath given by path is a main Go package, otherwise false.
 func IsMainPkg(path string) bool {
 	fis, err := ioutil.ReadDir(path)
@@ -0,0 +1,280 @@
+// Copyright 2022 tobbstr. All rights reserved.
+// Use of this source code is governed by a MIT-
+// license that can be found in the LICENSE file.
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeGoMod(t *testing.T, contents string) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(contents), 0o644)
+	require.NoError(t, err)
+	return dir
+}
+
+func writeGoWork(t *testing.T, contents string) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "go.work"), []byte(contents), 0o644)
+	require.NoError(t, err)
+	return dir
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		contents   string
+		wantPath   string
+		wantGo     string
+		wantErr    bool
+		checkExtra func(t *testing.T, info *ModuleInfo)
+	}{
+		{
+			name:     "plain module path",
+			contents: "module github.com/johndoe/example\n\ngo 1.20\n",
+			wantPath: "github.com/johndoe/example",
+			wantGo:   "1.20",
+		},
+		{
+			name:     "quoted module path",
+			contents: "module \"github.com/johndoe/example\"\n\ngo 1.20\n",
+			wantPath: "github.com/johndoe/example",
+			wantGo:   "1.20",
+		},
+		{
+			name: "replace directive with local path",
+			contents: "module github.com/johndoe/example\n\ngo 1.20\n\n" +
+				"require github.com/johndoe/lib v1.2.3\n\n" +
+				"replace github.com/johndoe/lib => ../lib\n",
+			wantPath: "github.com/johndoe/example",
+			wantGo:   "1.20",
+			checkExtra: func(t *testing.T, info *ModuleInfo) {
+				require := require.New(t)
+				require.Len(info.Require, 1)
+				require.Equal("github.com/johndoe/lib", info.Require[0].Mod.Path)
+				require.Len(info.Replace, 1)
+				require.Equal("github.com/johndoe/lib", info.Replace[0].Old.Path)
+				require.Equal("../lib", info.Replace[0].New.Path)
+			},
+		},
+		{
+			name:     "malformed go.mod",
+			contents: "this is not a go.mod file\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			require := require.New(t)
+			dir := writeGoMod(t, tt.contents)
+
+			// When
+			got, err := Parse(dir)
+
+			// Then
+			if tt.wantErr {
+				require.Error(err)
+				require.Nil(got)
+				return
+			}
+			require.NoError(err)
+			require.Equal(tt.wantPath, got.Path)
+			require.Equal(tt.wantGo, got.GoVersion)
+			if tt.checkExtra != nil {
+				tt.checkExtra(t, got)
+			}
+		})
+	}
+
+	t.Run("missing go.mod", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := t.TempDir()
+
+		// When
+		got, err := Parse(dir)
+
+		// Then
+		require.Error(err)
+		require.Nil(got)
+	})
+}
+
+func TestNameFrom(t *testing.T) {
+	t.Run("returns module path", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := writeGoMod(t, "module github.com/johndoe/example\n\ngo 1.20\n")
+
+		// When
+		got, err := NameFrom(dir)
+
+		// Then
+		require.NoError(err)
+		require.Equal("github.com/johndoe/example", got)
+	})
+
+	t.Run("returns quoted module path", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := writeGoMod(t, "module \"github.com/johndoe/example\"\n\ngo 1.20\n")
+
+		// When
+		got, err := NameFrom(dir)
+
+		// Then
+		require.NoError(err)
+		require.Equal("github.com/johndoe/example", got)
+	})
+
+	t.Run("returns error for malformed go.mod", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := writeGoMod(t, "this is not a go.mod file\n")
+
+		// When
+		got, err := NameFrom(dir)
+
+		// Then
+		require.Error(err)
+		require.Empty(got)
+	})
+
+	t.Run("returns error when go.mod is missing", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := t.TempDir()
+
+		// When
+		got, err := NameFrom(dir)
+
+		// Then
+		require.Error(err)
+		require.Empty(got)
+	})
+}
+
+func TestKind(t *testing.T) {
+	t.Run("workspace root", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := writeGoWork(t, "go 1.20\n\nuse (\n\t./a\n\t./b\n)\n")
+
+		// When
+		got := Kind(dir)
+
+		// Then
+		require.Equal(RootWorkspace, got)
+	})
+
+	t.Run("module root", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := writeGoMod(t, "module github.com/johndoe/example\n\ngo 1.20\n")
+
+		// When
+		got := Kind(dir)
+
+		// Then
+		require.Equal(RootModule, got)
+	})
+
+	t.Run("neither", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := t.TempDir()
+
+		// When
+		got := Kind(dir)
+
+		// Then
+		require.Equal(RootNone, got)
+	})
+
+	t.Run("workspace takes precedence over module", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := writeGoWork(t, "go 1.20\n\nuse ./a\n")
+		err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/johndoe/example\n"), 0o644)
+		require.NoError(err)
+
+		// When
+		got := Kind(dir)
+
+		// Then
+		require.Equal(RootWorkspace, got)
+	})
+}
+
+func TestParseWorkspace(t *testing.T) {
+	t.Run("parses use directives into member paths", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := writeGoWork(t, "go 1.20\n\nuse (\n\t./a\n\t./b\n)\n")
+
+		// When
+		got, err := ParseWorkspace(dir)
+
+		// Then
+		require.NoError(err)
+		require.Equal(dir, got.RootPath)
+		require.Equal("1.20", got.GoVersion)
+		require.Equal([]string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}, got.Members)
+	})
+
+	t.Run("returns error when go.work is missing", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := t.TempDir()
+
+		// When
+		got, err := ParseWorkspace(dir)
+
+		// Then
+		require.Error(err)
+		require.Nil(got)
+	})
+
+	t.Run("returns error for malformed go.work", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		dir := writeGoWork(t, "this is not a go.work file\n")
+
+		// When
+		got, err := ParseWorkspace(dir)
+
+		// Then
+		require.Error(err)
+		require.Nil(got)
+	})
+}
+
+func TestWorkspaceRootFromWorkingDir(t *testing.T) {
+	t.Run("finds workspace root in a parent directory", func(t *testing.T) {
+		// Given
+		require := require.New(t)
+		root := writeGoWork(t, "go 1.20\n\nuse ./a\n")
+		nested := filepath.Join(root, "a", "b", "c")
+		require.NoError(os.MkdirAll(nested, 0o755))
+
+		wd, err := os.Getwd()
+		require.NoError(err)
+		require.NoError(os.Chdir(nested))
+		defer func() { require.NoError(os.Chdir(wd)) }()
+
+		// When
+		got, err := WorkspaceRootFromWorkingDir()
+
+		// Then
+		require.NoError(err)
+		require.Equal(root, got)
+	})
+}